@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	lambdaGroupPattern = regexp.MustCompile(`^/aws/lambda/(.+)$`)
+	eksGroupPattern    = regexp.MustCompile(`^/aws/eks/([^/]+)/cluster$`)
+	vpcFlowLogPattern  = regexp.MustCompile(`^/vpc/([^/]+)/flowlogs$`)
+	cloudTrailPattern  = regexp.MustCompile(`^/aws/cloudtrail/(.+)$`)
+)
+
+// templateFuncs are the extra functions available to AttributeMapping templates,
+// borrowed from the handful of string helpers the Docker awslogs driver's
+// tag-template support relies on.
+var templateFuncs = template.FuncMap{
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// eventTemplateData is the value passed to AttributeMapping templates: once per
+// event for "attributes." templates, and once per log group batch, with only
+// LogGroupName and Tags set, for "resource." templates.
+type eventTemplateData struct {
+	LogGroupName  string
+	LogStreamName string
+	EventID       string
+	IngestionTime int64
+	Timestamp     int64
+	Tags          map[string]string
+}
+
+// compiledAttributeMapping is an AttributeMapping with its templates parsed once at
+// Start time rather than on every event.
+type compiledAttributeMapping struct {
+	// resource holds the "resource.*" entries, key is the attribute name with the
+	// "resource." prefix stripped.
+	resource map[string]*template.Template
+	// record holds the "attributes.*" entries, same stripping for "attributes.".
+	record map[string]*template.Template
+}
+
+func compileAttributeMapping(mapping map[string]string) (*compiledAttributeMapping, error) {
+	c := &compiledAttributeMapping{
+		resource: map[string]*template.Template{},
+		record:   map[string]*template.Template{},
+	}
+
+	for key, tmplStr := range mapping {
+		tmpl, err := template.New(key).Funcs(templateFuncs).Parse(tmplStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %q: %w", key, err)
+		}
+
+		switch {
+		case strings.HasPrefix(key, "resource."):
+			c.resource[strings.TrimPrefix(key, "resource.")] = tmpl
+		case strings.HasPrefix(key, "attributes."):
+			c.record[strings.TrimPrefix(key, "attributes.")] = tmpl
+		default:
+			return nil, fmt.Errorf("attribute_mapping key %q must start with \"resource.\" or \"attributes.\"", key)
+		}
+	}
+
+	return c, nil
+}
+
+func execTemplate(tmpl *template.Template, data eventTemplateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// wellKnownGroupAttributes extracts resource attributes from common CloudWatch log
+// group name shapes, the same way the Docker awslogs driver infers a container name
+// from well-known path conventions. These apply before any user-supplied
+// AttributeMapping, which can still override them with an explicit key.
+func wellKnownGroupAttributes(groupName string) map[string]string {
+	attrs := map[string]string{}
+
+	switch {
+	case lambdaGroupPattern.MatchString(groupName):
+		m := lambdaGroupPattern.FindStringSubmatch(groupName)
+		attrs["faas.name"] = m[1]
+	case eksGroupPattern.MatchString(groupName):
+		m := eksGroupPattern.FindStringSubmatch(groupName)
+		attrs["k8s.cluster.name"] = m[1]
+	case vpcFlowLogPattern.MatchString(groupName):
+		m := vpcFlowLogPattern.FindStringSubmatch(groupName)
+		attrs["aws.vpc.id"] = m[1]
+	case cloudTrailPattern.MatchString(groupName):
+		attrs["aws.log.type"] = "cloudtrail"
+	}
+
+	return attrs
+}
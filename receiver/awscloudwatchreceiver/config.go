@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Supported values for LogsConfig.Mode.
+const (
+	LogsModePoll     = "poll"
+	LogsModeLiveTail = "live_tail"
+)
+
+var (
+	errNoLogsConfigured         = errors.New("no logs configured, need one of `logs.groups.named`, `logs.groups.autodiscover`, or `logs.groups.insights`")
+	errInvalidAutodiscoverLimit = errors.New("`logs.groups.autodiscover.limit` must be greater than 0")
+	errInvalidMode              = errors.New("`logs.mode` must be either `poll` or `live_tail`")
+	errOneShotRequiresWindow    = errors.New("`logs.groups.one_shot` requires `logs.groups.start_time` or `logs.groups.end_time` to bound the backfill")
+	errOneShotWithLiveTail      = errors.New("`logs.groups.one_shot` cannot be combined with `logs.mode: live_tail`")
+	errOneShotWithInsights      = errors.New("`logs.groups.one_shot` cannot be combined with `logs.groups.insights`")
+	errInsightsWithOtherGroups  = errors.New("`logs.groups.insights` cannot be combined with `logs.groups.named` or `logs.groups.autodiscover`")
+	errInsightsWithLiveTail     = errors.New("`logs.groups.insights` cannot be combined with `logs.mode: live_tail`")
+	errInsightsRequiresQuery    = errors.New("`logs.groups.insights.query_string` is required")
+	errInsightsRequiresGroups   = errors.New("`logs.groups.insights` requires either `log_group_names` or `log_group_name_prefix`")
+	errInsightsRequiresWindow   = errors.New("`logs.groups.insights.time_window` must be greater than 0")
+	errInsightsRequiresSchedule = errors.New("`logs.groups.insights.schedule` must be greater than 0")
+)
+
+// Config is the overall config structure for the awscloudwatchreceiver.
+type Config struct {
+	// Region is the AWS region the log groups being queried belong to, e.g.
+	// us-east-1. If unset, the receiver discovers it at Start from the EC2
+	// instance metadata service, or from ECS task metadata v4 when running under
+	// ECS (ECS_CONTAINER_METADATA_URI_V4 is set by the ECS agent).
+	Region string `mapstructure:"region"`
+	// Profile is the AWS profile used to authenticate against the AWS API, if unset the
+	// default credential chain is used.
+	Profile string `mapstructure:"profile"`
+	// IMDSEndpoint overrides the EC2 instance metadata service address used for
+	// region discovery. Mainly useful for pointing tests at a fake IMDS.
+	IMDSEndpoint string `mapstructure:"imds_endpoint"`
+	// Logs configures the log collection behavior of the receiver. It is required as
+	// this receiver does not currently support collecting metrics.
+	Logs LogsConfig `mapstructure:"logs"`
+}
+
+// LogsConfig configures which log groups/streams are collected and how often.
+type LogsConfig struct {
+	// Mode selects how events are retrieved from CloudWatch Logs: "poll" (the
+	// default) repeatedly calls FilterLogEvents every PollInterval, while
+	// "live_tail" opens one long-lived StartLiveTail stream per batch of up to 10
+	// log groups and forwards events as CloudWatch emits them. In "live_tail" mode
+	// only the log group names are passed to StartLiveTail: the stream Names and
+	// Prefixes filters are not applied, so every stream of each group is forwarded.
+	Mode string `mapstructure:"mode"`
+	// PollInterval is the duration waited between polling calls made against the
+	// FilterLogEvents API. It is ignored when Mode is "live_tail".
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// MaxEventsPerRequest is the maximum number of events to process per FilterLogEvents
+	// call.
+	MaxEventsPerRequest int `mapstructure:"max_events_per_request"`
+	// Groups configures the set of log groups and streams collected by the receiver.
+	Groups GroupConfig `mapstructure:"groups"`
+	// StorageID names a storage extension used to persist, per log group/stream
+	// filter, the in-flight NextToken and last-consumed event timestamp across
+	// restarts. If unset, state does not survive a restart.
+	StorageID *component.ID `mapstructure:"storage"`
+	// AttributeMapping maps an OTel attribute name to a Go template string. The key
+	// must start with "resource." to set a resource attribute or "attributes." to
+	// set a log record attribute,
+	// e.g. `resource.service.name: "{{ .LogGroupName | trimPrefix \"/aws/lambda/\" }}"`.
+	// "attributes." templates are executed once per event. "resource." templates
+	// are executed once per log group in each batch of events and see only
+	// LogGroupName and Tags; the per-event fields are empty for them.
+	AttributeMapping map[string]string `mapstructure:"attribute_mapping"`
+}
+
+// GroupConfig allows either an explicit, named set of log groups, or an autodiscovery
+// mechanism that queries DescribeLogGroups to build the set at Start time.
+type GroupConfig struct {
+	AutodiscoverConfig *AutodiscoverConfig     `mapstructure:"autodiscover"`
+	NamedConfigs       map[string]StreamConfig `mapstructure:"named"`
+
+	// StartTime bounds the backfill window. It accepts an RFC3339 timestamp or a
+	// duration relative to now, e.g. "-24h". Defaults to the epoch when OneShot is
+	// set and StartTime is empty.
+	StartTime string `mapstructure:"start_time"`
+	// EndTime bounds the backfill window the same way StartTime does. Defaults to
+	// the current time when OneShot is set and EndTime is empty.
+	EndTime string `mapstructure:"end_time"`
+	// OneShot switches the receiver from continuous polling to a bounded historical
+	// backfill: every matched group/stream is paginated across [StartTime, EndTime]
+	// once, after which the receiver stops polling instead of continuing on
+	// PollInterval.
+	OneShot bool `mapstructure:"one_shot"`
+
+	// InsightsConfig, if set, switches the receiver to a third mode: instead of
+	// calling FilterLogEvents against NamedConfigs/AutodiscoverConfig, it runs a
+	// CloudWatch Logs Insights query on a schedule and emits each result row as a
+	// log record. Mutually exclusive with NamedConfigs and AutodiscoverConfig.
+	InsightsConfig *InsightsConfig `mapstructure:"insights"`
+}
+
+// InsightsConfig configures a scheduled CloudWatch Logs Insights query used as an
+// alternative to FilterLogEvents-based collection, letting users pre-filter and
+// parse at the AWS side (stats, parse, filter) instead of pulling raw events.
+type InsightsConfig struct {
+	// QueryString is the Logs Insights query to run, using the Insights query
+	// syntax, e.g. "fields @timestamp, @message | filter @message like /ERROR/".
+	QueryString string `mapstructure:"query_string"`
+	// LogGroupNames lists the log groups to query. Mutually exclusive with
+	// LogGroupNamePrefix.
+	LogGroupNames []string `mapstructure:"log_group_names"`
+	// LogGroupNamePrefix queries every log group whose name begins with this
+	// value. Mutually exclusive with LogGroupNames.
+	LogGroupNamePrefix string `mapstructure:"log_group_name_prefix"`
+	// TimeWindow is the width of the rolling [now-TimeWindow, now] window each
+	// query covers, e.g. "5m".
+	TimeWindow time.Duration `mapstructure:"time_window"`
+	// Schedule is how often the query is re-run. Only a Go duration is currently
+	// supported; cron expressions are not.
+	Schedule time.Duration `mapstructure:"schedule"`
+}
+
+// AutodiscoverConfig controls how the receiver discovers log groups via the
+// DescribeLogGroups API instead of requiring the user to name them explicitly.
+type AutodiscoverConfig struct {
+	// Limit is the maximum number of log groups that will be autodiscovered.
+	Limit int `mapstructure:"limit"`
+	// Prefix restricts autodiscovery to log groups whose name begins with this value.
+	Prefix string `mapstructure:"prefix"`
+	// Streams further restricts, within each discovered log group, which streams are
+	// collected.
+	Streams StreamConfig `mapstructure:"streams"`
+}
+
+// StreamConfig filters which log streams within a log group are collected.
+type StreamConfig struct {
+	Prefixes []*string `mapstructure:"prefixes"`
+	Names    []*string `mapstructure:"names"`
+}
+
+func (c *Config) Validate() error {
+	switch c.Logs.Mode {
+	case "", LogsModePoll, LogsModeLiveTail:
+	default:
+		return errInvalidMode
+	}
+
+	if c.Logs.Groups.AutodiscoverConfig == nil && len(c.Logs.Groups.NamedConfigs) == 0 && c.Logs.Groups.InsightsConfig == nil {
+		return errNoLogsConfigured
+	}
+
+	if c.Logs.Groups.AutodiscoverConfig != nil && c.Logs.Groups.AutodiscoverConfig.Limit <= 0 {
+		return errInvalidAutodiscoverLimit
+	}
+
+	if c.Logs.Groups.OneShot {
+		if c.Logs.Mode == LogsModeLiveTail {
+			return errOneShotWithLiveTail
+		}
+		if c.Logs.Groups.InsightsConfig != nil {
+			return errOneShotWithInsights
+		}
+		if c.Logs.Groups.StartTime == "" && c.Logs.Groups.EndTime == "" {
+			return errOneShotRequiresWindow
+		}
+	}
+
+	if ic := c.Logs.Groups.InsightsConfig; ic != nil {
+		if c.Logs.Groups.AutodiscoverConfig != nil || len(c.Logs.Groups.NamedConfigs) > 0 {
+			return errInsightsWithOtherGroups
+		}
+		if c.Logs.Mode == LogsModeLiveTail {
+			return errInsightsWithLiveTail
+		}
+		if ic.QueryString == "" {
+			return errInsightsRequiresQuery
+		}
+		if len(ic.LogGroupNames) == 0 && ic.LogGroupNamePrefix == "" {
+			return errInsightsRequiresGroups
+		}
+		if ic.TimeWindow <= 0 {
+			return errInsightsRequiresWindow
+		}
+		if ic.Schedule <= 0 {
+			return errInsightsRequiresSchedule
+		}
+	}
+
+	if _, err := parseConfiguredTime(c.Logs.Groups.StartTime, time.Time{}); err != nil {
+		return err
+	}
+	if _, err := parseConfiguredTime(c.Logs.Groups.EndTime, time.Time{}); err != nil {
+		return err
+	}
+
+	if _, err := compileAttributeMapping(c.Logs.AttributeMapping); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseConfiguredTime parses a GroupConfig.StartTime/EndTime value. An empty value
+// returns fallback unchanged. A value is either an RFC3339 timestamp or a duration
+// relative to now, e.g. "-24h".
+func parseConfiguredTime(s string, fallback time.Time) (time.Time, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: must be RFC3339 or a relative duration like \"-24h\": %w", s, err)
+	}
+	return t, nil
+}
+
+var _ component.Config = (*Config)(nil)
@@ -0,0 +1,6 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package awscloudwatchreceiver implements a receiver that can be used by the
+// Opentelemetry collector to retrieve logs from Amazon CloudWatch.
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
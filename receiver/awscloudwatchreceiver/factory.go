@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+)
+
+const (
+	typeStr   = "awscloudwatch"
+	stability = component.StabilityLevelAlpha
+
+	defaultPollInterval        = time.Minute
+	defaultMaxEventsPerRequest = 1000
+)
+
+// NewFactory creates a factory for the awscloudwatch receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		component.MustNewType(typeStr),
+		createDefaultConfig,
+		receiver.WithLogs(createLogsReceiver, stability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Logs: LogsConfig{
+			PollInterval:        defaultPollInterval,
+			MaxEventsPerRequest: defaultMaxEventsPerRequest,
+		},
+	}
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	params receiver.CreateSettings,
+	rConf component.Config,
+	consumer consumer.Logs,
+) (receiver.Logs, error) {
+	cfg := rConf.(*Config)
+	rcvr := newLogsReceiver(cfg, params.Logger, consumer)
+	rcvr.id = params.ID
+	return rcvr, nil
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// insightsResultPollInterval is how often GetQueryResults is polled while a Logs
+// Insights query is still running.
+const insightsResultPollInterval = time.Second
+
+// runInsights runs ic's query on a rolling [now-TimeWindow, now] window every
+// Schedule, until Shutdown closes l.doneCh.
+func (l *logsReceiver) runInsights(ctx context.Context, ic *InsightsConfig) {
+	defer l.wg.Done()
+
+	l.runInsightsQuery(ctx, ic)
+
+	t := time.NewTicker(ic.Schedule)
+	defer t.Stop()
+	for {
+		select {
+		case <-l.doneCh:
+			return
+		case <-t.C:
+			l.runInsightsQuery(ctx, ic)
+		}
+	}
+}
+
+func (l *logsReceiver) runInsightsQuery(ctx context.Context, ic *InsightsConfig) {
+	end := time.Now()
+	start := end.Add(-ic.TimeWindow)
+
+	input := &cloudwatchlogs.StartQueryInput{
+		QueryString: aws.String(ic.QueryString),
+		StartTime:   aws.Int64(start.Unix()),
+		EndTime:     aws.Int64(end.Unix()),
+	}
+	if ic.LogGroupNamePrefix != "" {
+		input.LogGroupNamePrefix = aws.String(ic.LogGroupNamePrefix)
+	} else {
+		input.LogGroupNames = aws.StringSlice(ic.LogGroupNames)
+	}
+
+	out, err := l.client.StartQueryWithContext(ctx, input)
+	if err != nil {
+		l.logger.Error("unable to start logs insights query", zap.Error(err))
+		return
+	}
+
+	results, err := l.waitForInsightsResults(ctx, out.QueryId)
+	if err != nil {
+		l.logger.Error("logs insights query did not complete", zap.Error(err))
+		return
+	}
+	if len(results) == 0 {
+		return
+	}
+
+	if err := l.consumer.ConsumeLogs(ctx, l.processInsightsResults(results)); err != nil {
+		l.logger.Error("unable to consume logs insights results", zap.Error(err))
+	}
+}
+
+// waitForInsightsResults polls GetQueryResults until the query reaches a terminal
+// status. If Shutdown fires first, it cancels the query via StopQuery and returns
+// a nil result with no error, since that's an intentional stop, not a failure.
+func (l *logsReceiver) waitForInsightsResults(ctx context.Context, queryID *string) ([][]*cloudwatchlogs.ResultField, error) {
+	t := time.NewTicker(insightsResultPollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-l.doneCh:
+			_, _ = l.client.StopQueryWithContext(ctx, &cloudwatchlogs.StopQueryInput{QueryId: queryID})
+			return nil, nil
+		case <-t.C:
+			out, err := l.client.GetQueryResultsWithContext(ctx, &cloudwatchlogs.GetQueryResultsInput{QueryId: queryID})
+			if err != nil {
+				return nil, err
+			}
+
+			switch aws.StringValue(out.Status) {
+			case cloudwatchlogs.QueryStatusComplete:
+				return out.Results, nil
+			case cloudwatchlogs.QueryStatusFailed, cloudwatchlogs.QueryStatusCancelled, cloudwatchlogs.QueryStatusTimeout:
+				return nil, fmt.Errorf("query %s ended with status %s", aws.StringValue(queryID), aws.StringValue(out.Status))
+			}
+		}
+	}
+}
+
+// processInsightsResults turns a GetQueryResults row, itself a list of field/value
+// pairs, into one log record per row, with every field becoming a record
+// attribute. The "@message" field, present whenever the query selects it, also
+// becomes the record body.
+func (l *logsReceiver) processInsightsResults(results [][]*cloudwatchlogs.ResultField) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	resourceAttrs := rl.Resource().Attributes()
+	resourceAttrs.PutStr("cloud.provider", "aws")
+	resourceAttrs.PutStr("cloud.region", l.region)
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	for _, row := range results {
+		lr := sl.LogRecords().AppendEmpty()
+		attrs := lr.Attributes()
+
+		for _, field := range row {
+			name := aws.StringValue(field.Field)
+			value := aws.StringValue(field.Value)
+			attrs.PutStr(name, value)
+			if name == "@message" {
+				lr.Body().SetStr(value)
+			}
+		}
+	}
+
+	return logs
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxLiveTailGroupsPerSession is the AWS-enforced limit on the number of log
+	// group identifiers a single StartLiveTail session may cover.
+	maxLiveTailGroupsPerSession = 10
+
+	liveTailInitialBackoff = time.Second
+	liveTailMaxBackoff     = 30 * time.Second
+)
+
+// batchGroupNames splits names into chunks of at most size, preserving order.
+func batchGroupNames(names []string, size int) [][]string {
+	var batches [][]string
+	for len(names) > 0 {
+		n := size
+		if n > len(names) {
+			n = len(names)
+		}
+		batches = append(batches, names[:n])
+		names = names[n:]
+	}
+	return batches
+}
+
+// liveTailSession owns a single StartLiveTail stream covering up to
+// maxLiveTailGroupsPerSession log groups. It reconnects with exponential backoff
+// whenever the stream ends, whether from a SessionTimeoutException (CloudWatch tears
+// down every Live Tail session after three hours) or any other stream error.
+type liveTailSession struct {
+	receiver   *logsReceiver
+	groupNames []string
+}
+
+func newLiveTailSession(r *logsReceiver, groupNames []string) *liveTailSession {
+	return &liveTailSession{receiver: r, groupNames: groupNames}
+}
+
+func (s *liveTailSession) run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	backoff := liveTailInitialBackoff
+	for {
+		select {
+		case <-s.receiver.doneCh:
+			return
+		default:
+		}
+
+		err := s.stream(ctx)
+		if err == nil {
+			// The stream ended because Shutdown was called.
+			return
+		}
+
+		s.receiver.logger.Warn("live tail session ended, reconnecting",
+			zap.Strings("log_groups", s.groupNames),
+			zap.Error(err),
+			zap.Duration("backoff", backoff))
+
+		select {
+		case <-s.receiver.doneCh:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > liveTailMaxBackoff {
+			backoff = liveTailMaxBackoff
+		}
+	}
+}
+
+// stream opens one StartLiveTail session and forwards events until it ends, either
+// because the server closed it, an error occurred, or Shutdown was called. It
+// returns nil only when Shutdown was called, meaning the caller should stop
+// retrying; a server close without an error is reported as an error.
+func (s *liveTailSession) stream(ctx context.Context) error {
+	identifiers := make([]*string, len(s.groupNames))
+	for i := range s.groupNames {
+		identifiers[i] = aws.String(s.groupNames[i])
+	}
+
+	stream, err := s.receiver.client.StartLiveTailWithContext(ctx, &cloudwatchlogs.StartLiveTailInput{
+		LogGroupIdentifiers: identifiers,
+	})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	events := stream.Events()
+	for {
+		select {
+		case <-s.receiver.doneCh:
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				if serr := stream.Err(); serr != nil {
+					return serr
+				}
+				return errors.New("live tail stream closed by server")
+			}
+
+			switch e := event.(type) {
+			case *cloudwatchlogs.LiveTailSessionStart:
+				s.receiver.logger.Info("live tail session started",
+					zap.String("session_id", aws.StringValue(e.SessionId)))
+			case *cloudwatchlogs.LiveTailSessionUpdate:
+				if len(e.SessionResults) == 0 {
+					continue
+				}
+				// The consumer applies its own back-pressure: ConsumeLogs blocks
+				// until downstream can accept the batch, which in turn blocks this
+				// read loop from pulling further events off the stream.
+				if cerr := s.receiver.consumer.ConsumeLogs(ctx, s.processSessionUpdate(e)); cerr != nil {
+					return cerr
+				}
+			}
+		}
+	}
+}
+
+// processSessionUpdate converts one LiveTailSessionUpdate into plog.Logs, grouping
+// results by log group the same way the poll path's processEvents does, so
+// wellKnownGroupAttributes and AttributeMapping apply consistently regardless of
+// which mode collected the event.
+func (s *liveTailSession) processSessionUpdate(update *cloudwatchlogs.LiveTailSessionUpdate) plog.Logs {
+	logs := plog.NewLogs()
+
+	var groupOrder []string
+	byGroup := make(map[string][]*cloudwatchlogs.LiveTailSessionLogResult)
+	for _, result := range update.SessionResults {
+		groupName := aws.StringValue(result.LogGroupIdentifier)
+		if _, ok := byGroup[groupName]; !ok {
+			groupOrder = append(groupOrder, groupName)
+		}
+		byGroup[groupName] = append(byGroup[groupName], result)
+	}
+
+	for _, groupName := range groupOrder {
+		rl := logs.ResourceLogs().AppendEmpty()
+		resourceAttrs := rl.Resource().Attributes()
+		resourceAttrs.PutStr("cloud.provider", "aws")
+		resourceAttrs.PutStr("cloud.region", s.receiver.region)
+		resourceAttrs.PutStr("aws.log.group.name", groupName)
+		for k, v := range wellKnownGroupAttributes(groupName) {
+			resourceAttrs.PutStr(k, v)
+		}
+		s.receiver.applyResourceAttributeMapping(resourceAttrs, groupName)
+
+		sl := rl.ScopeLogs().AppendEmpty()
+		for _, result := range byGroup[groupName] {
+			lr := sl.LogRecords().AppendEmpty()
+			lr.Body().SetStr(aws.StringValue(result.Message))
+			lr.SetTimestamp(pcommon.Timestamp(aws.Int64Value(result.Timestamp) * int64(time.Millisecond)))
+
+			attrs := lr.Attributes()
+			attrs.PutStr("aws.log.stream.name", aws.StringValue(result.LogStreamName))
+			attrs.PutStr("aws.log.event.id", aws.StringValue(result.EventId))
+			attrs.PutInt("aws.log.ingestion.time", aws.Int64Value(result.IngestionTime))
+
+			s.receiver.applyRecordAttributeMapping(attrs, groupName, eventTemplateData{
+				LogStreamName: aws.StringValue(result.LogStreamName),
+				EventID:       aws.StringValue(result.EventId),
+				IngestionTime: aws.Int64Value(result.IngestionTime),
+				Timestamp:     aws.Int64Value(result.Timestamp),
+			})
+		}
+	}
+
+	return logs
+}
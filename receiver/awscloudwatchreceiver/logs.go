@@ -0,0 +1,533 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// client is the subset of the cloudwatchlogs API surface the receiver depends on. It
+// exists so that tests can substitute a mock implementation.
+type client interface {
+	DescribeLogGroupsWithContext(ctx context.Context, input *cloudwatchlogs.DescribeLogGroupsInput, opts ...request.Option) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	FilterLogEventsWithContext(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput, opts ...request.Option) (*cloudwatchlogs.FilterLogEventsOutput, error)
+	StartLiveTailWithContext(ctx context.Context, input *cloudwatchlogs.StartLiveTailInput, opts ...request.Option) (liveTailStream, error)
+	ListTagsLogGroupWithContext(ctx context.Context, input *cloudwatchlogs.ListTagsLogGroupInput, opts ...request.Option) (*cloudwatchlogs.ListTagsLogGroupOutput, error)
+	StartQueryWithContext(ctx context.Context, input *cloudwatchlogs.StartQueryInput, opts ...request.Option) (*cloudwatchlogs.StartQueryOutput, error)
+	GetQueryResultsWithContext(ctx context.Context, input *cloudwatchlogs.GetQueryResultsInput, opts ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, opts ...request.Option) (*cloudwatchlogs.StopQueryOutput, error)
+}
+
+// liveTailStream is the event-stream side of StartLiveTail, pulled out as its own
+// interface (rather than the concrete SDK event stream type) so tests can drive it
+// with a plain channel the same way the mock client drives the other two calls.
+type liveTailStream interface {
+	Events() <-chan cloudwatchlogs.StartLiveTailResponseStreamEvent
+	Close() error
+	Err() error
+}
+
+// awsClient adapts the real cloudwatchlogs SDK client to the client interface,
+// unwrapping StartLiveTail's response into the bare liveTailStream.
+type awsClient struct {
+	*cloudwatchlogs.CloudWatchLogs
+}
+
+func (a *awsClient) StartLiveTailWithContext(ctx context.Context, input *cloudwatchlogs.StartLiveTailInput, opts ...request.Option) (liveTailStream, error) {
+	out, err := a.CloudWatchLogs.StartLiveTailWithContext(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out.GetStream(), nil
+}
+
+// groupRequest is a single FilterLogEvents call spec. The FilterLogEvents API accepts
+// either an explicit list of stream names or a single stream name prefix, but not
+// both, so a StreamConfig with both set expands into multiple groupRequests.
+type groupRequest struct {
+	groupName string
+	prefix    *string
+	names     []*string
+	nextToken *string
+
+	// lastEventTimestamp is the highest event Timestamp among events already
+	// consumed for this tuple. It's persisted via the storage extension and used
+	// as a StartTime floor once nextToken is exhausted, to avoid reopening a data
+	// gap across a restart. FilterLogEvents' StartTime filters on event Timestamp,
+	// not IngestionTime, and IngestionTime >= Timestamp, so bounding on
+	// IngestionTime could silently skip events that arrived late relative to an
+	// earlier batch's watermark.
+	lastEventTimestamp int64
+	// seenAtLastEventTimestamp holds the EventIDs already consumed with
+	// Timestamp == lastEventTimestamp. StartTime is inclusive, so the next query
+	// after a restart re-returns every event at that exact millisecond; this lets
+	// pollForLogs drop the ones already sent downstream instead of duplicating
+	// them.
+	seenAtLastEventTimestamp map[string]struct{}
+}
+
+// expandStreamConfig turns a single StreamConfig into the groupRequests needed to
+// cover it, since FilterLogEvents cannot combine a name prefix with an explicit name
+// list in one call. An empty StreamConfig yields one request covering every stream.
+func expandStreamConfig(groupName string, streams StreamConfig) []groupRequest {
+	var requests []groupRequest
+	for _, prefix := range streams.Prefixes {
+		requests = append(requests, groupRequest{groupName: groupName, prefix: prefix})
+	}
+	if len(streams.Names) > 0 {
+		requests = append(requests, groupRequest{groupName: groupName, names: streams.Names})
+	}
+	if len(requests) == 0 {
+		requests = append(requests, groupRequest{groupName: groupName})
+	}
+	return requests
+}
+
+// dedupeAndAdvance drops any event already consumed in a prior batch at the current
+// watermark (possible right after a restart, since the StartTime floor is
+// inclusive) and advances lastEventTimestamp/seenAtLastEventTimestamp to cover the
+// new batch.
+func (gr *groupRequest) dedupeAndAdvance(events []*cloudwatchlogs.FilteredLogEvent) []*cloudwatchlogs.FilteredLogEvent {
+	fresh := make([]*cloudwatchlogs.FilteredLogEvent, 0, len(events))
+	for _, event := range events {
+		ts := aws.Int64Value(event.Timestamp)
+		id := aws.StringValue(event.EventId)
+
+		switch {
+		case gr.lastEventTimestamp > 0 && ts == gr.lastEventTimestamp:
+			if _, dup := gr.seenAtLastEventTimestamp[id]; dup {
+				continue
+			}
+			gr.seenAtLastEventTimestamp[id] = struct{}{}
+		case ts > gr.lastEventTimestamp:
+			gr.lastEventTimestamp = ts
+			gr.seenAtLastEventTimestamp = map[string]struct{}{id: {}}
+		}
+		fresh = append(fresh, event)
+	}
+	return fresh
+}
+
+type logsReceiver struct {
+	id     component.ID
+	region string
+	logger *zap.Logger
+
+	client        client
+	consumer      consumer.Logs
+	storageClient storage.Client
+	// host is retained only so a completed one_shot backfill can report it via
+	// host.ReportFatalError, since that's the only channel a receiver has to tell
+	// the collector there's nothing further for it to do.
+	host component.Host
+	// regionProvider discovers l.region when cfg.Region is empty. Left nil in
+	// production, where Start picks the right provider for the environment; tests
+	// set it to a fake to exercise discovery without a real IMDS/ECS endpoint.
+	regionProvider regionProvider
+
+	cfg           *Config
+	groupRequests []groupRequest
+
+	attributeMapping *compiledAttributeMapping
+	// groupTags caches ListTagsLogGroup results by group name so AttributeMapping
+	// templates can reference a group's tags without a call per event.
+	groupTags map[string]map[string]string
+
+	// startTimeMs/endTimeMs bound the FilterLogEvents window when Groups.OneShot is
+	// set; both are Unix milliseconds, as the CloudWatch Logs API expects.
+	startTimeMs int64
+	endTimeMs   int64
+
+	wg     *sync.WaitGroup
+	doneCh chan struct{}
+}
+
+func newLogsReceiver(cfg *Config, logger *zap.Logger, consumer consumer.Logs) *logsReceiver {
+	return &logsReceiver{
+		region:   cfg.Region,
+		logger:   logger,
+		consumer: consumer,
+		cfg:      cfg,
+		wg:       &sync.WaitGroup{},
+		doneCh:   make(chan struct{}),
+	}
+}
+
+func (l *logsReceiver) Start(ctx context.Context, host component.Host) error {
+	l.host = host
+
+	if err := l.discoverRegion(ctx); err != nil {
+		return err
+	}
+
+	if l.client == nil {
+		sess, err := session.NewSession(&aws.Config{
+			Region: aws.String(l.region),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create AWS session: %w", err)
+		}
+		l.client = &awsClient{cloudwatchlogs.New(sess)}
+	}
+
+	if l.storageClient == nil {
+		sc, err := getStorageClient(ctx, host, l.cfg.Logs.StorageID, l.id)
+		if err != nil {
+			return fmt.Errorf("unable to connect to storage: %w", err)
+		}
+		l.storageClient = sc
+	}
+
+	if l.attributeMapping == nil {
+		am, err := compileAttributeMapping(l.cfg.Logs.AttributeMapping)
+		if err != nil {
+			return err
+		}
+		l.attributeMapping = am
+	}
+
+	if ic := l.cfg.Logs.Groups.InsightsConfig; ic != nil {
+		l.wg.Add(1)
+		go l.runInsights(ctx, ic)
+		return nil
+	}
+
+	if err := l.buildGroupRequests(ctx); err != nil {
+		return err
+	}
+	if l.cfg.Logs.Mode != LogsModeLiveTail {
+		l.loadCheckpoints(ctx)
+	}
+	if len(l.cfg.Logs.AttributeMapping) > 0 {
+		l.loadGroupTags(ctx)
+	}
+
+	if l.cfg.Logs.Groups.OneShot {
+		start, err := parseConfiguredTime(l.cfg.Logs.Groups.StartTime, time.Unix(0, 0))
+		if err != nil {
+			return err
+		}
+		end, err := parseConfiguredTime(l.cfg.Logs.Groups.EndTime, time.Now())
+		if err != nil {
+			return err
+		}
+		l.startTimeMs = start.UnixMilli()
+		l.endTimeMs = end.UnixMilli()
+	}
+
+	if l.cfg.Logs.Mode == LogsModeLiveTail {
+		for _, batch := range batchGroupNames(l.groupNames(), maxLiveTailGroupsPerSession) {
+			lt := newLiveTailSession(l, batch)
+			l.wg.Add(1)
+			go lt.run(ctx, l.wg)
+		}
+		return nil
+	}
+
+	l.wg.Add(1)
+	go l.startPolling(ctx)
+	return nil
+}
+
+// groupNames returns the distinct log group names covered by l.groupRequests.
+func (l *logsReceiver) groupNames() []string {
+	seen := make(map[string]struct{}, len(l.groupRequests))
+	var names []string
+	for _, gr := range l.groupRequests {
+		if _, ok := seen[gr.groupName]; ok {
+			continue
+		}
+		seen[gr.groupName] = struct{}{}
+		names = append(names, gr.groupName)
+	}
+	return names
+}
+
+func (l *logsReceiver) Shutdown(ctx context.Context) error {
+	close(l.doneCh)
+	l.wg.Wait()
+
+	if l.storageClient == nil {
+		return nil
+	}
+	var errs error
+	if l.cfg.Logs.Mode != LogsModeLiveTail {
+		errs = l.flushCheckpoints(ctx)
+	}
+	errs = multierr.Append(errs, l.storageClient.Close(ctx))
+	return errs
+}
+
+func (l *logsReceiver) buildGroupRequests(ctx context.Context) error {
+	if l.cfg.Logs.Groups.AutodiscoverConfig != nil {
+		return l.discoverGroupRequests(ctx)
+	}
+
+	for name, streams := range l.cfg.Logs.Groups.NamedConfigs {
+		l.groupRequests = append(l.groupRequests, expandStreamConfig(name, streams)...)
+	}
+	return nil
+}
+
+func (l *logsReceiver) discoverGroupRequests(ctx context.Context) error {
+	ac := l.cfg.Logs.Groups.AutodiscoverConfig
+
+	input := &cloudwatchlogs.DescribeLogGroupsInput{
+		Limit: aws.Int64(int64(ac.Limit)),
+	}
+	if ac.Prefix != "" {
+		input.LogGroupNamePrefix = aws.String(ac.Prefix)
+	}
+
+	discovered := 0
+	for {
+		out, err := l.client.DescribeLogGroupsWithContext(ctx, input)
+		if err != nil {
+			return fmt.Errorf("unable to discover log groups: %w", err)
+		}
+
+		for _, group := range out.LogGroups {
+			l.groupRequests = append(l.groupRequests, expandStreamConfig(aws.StringValue(group.LogGroupName), ac.Streams)...)
+			discovered++
+		}
+
+		if out.NextToken == nil || discovered >= ac.Limit {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return nil
+}
+
+func (l *logsReceiver) startPolling(ctx context.Context) {
+	defer l.wg.Done()
+
+	if l.cfg.Logs.Groups.OneShot {
+		err := l.poll(ctx)
+		if err != nil {
+			l.logger.Error("unable to complete one-shot backfill", zap.Error(err))
+		} else {
+			l.logger.Info("one-shot backfill complete, exiting")
+		}
+		l.reportOneShotDone(err)
+		return
+	}
+
+	t := time.NewTicker(l.cfg.Logs.PollInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-l.doneCh:
+			return
+		case <-t.C:
+			if err := l.poll(ctx); err != nil {
+				l.logger.Error("unable to poll for new logs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reportOneShotDone tells the host a one_shot backfill has run to completion, since
+// otherwise the collector has no way to observe that there's nothing left for this
+// receiver to do and keeps running idle until an operator kills it. pollErr, if
+// non-nil, is folded into the reported error rather than swallowed: the backfill is
+// over either way, successfully or not, and there's no further PollInterval tick
+// that could retry it.
+func (l *logsReceiver) reportOneShotDone(pollErr error) {
+	if l.host == nil {
+		return
+	}
+	err := errors.New("aws cloudwatch logs receiver: one-shot backfill complete")
+	if pollErr != nil {
+		err = fmt.Errorf("aws cloudwatch logs receiver: one-shot backfill finished with errors: %w", pollErr)
+	}
+	l.host.ReportFatalError(err)
+}
+
+// loadGroupTags fetches ListTagsLogGroup once per distinct log group so
+// AttributeMapping templates can reference them without a call per event. Start
+// only calls this when AttributeMapping is configured: ListTagsLogGroup is
+// account-wide throttled by AWS to a handful of requests per second, so users who
+// don't reference tags shouldn't pay for a call per group on every restart. A
+// failure here is non-fatal: the group is just treated as untagged.
+func (l *logsReceiver) loadGroupTags(ctx context.Context) {
+	l.groupTags = make(map[string]map[string]string, len(l.groupRequests))
+	for _, name := range l.groupNames() {
+		out, err := l.client.ListTagsLogGroupWithContext(ctx, &cloudwatchlogs.ListTagsLogGroupInput{
+			LogGroupName: aws.String(name),
+		})
+		if err != nil {
+			l.logger.Warn("unable to fetch log group tags", zap.String("log_group", name), zap.Error(err))
+			continue
+		}
+
+		tags := make(map[string]string, len(out.Tags))
+		for k, v := range out.Tags {
+			tags[k] = aws.StringValue(v)
+		}
+		l.groupTags[name] = tags
+	}
+}
+
+func (l *logsReceiver) poll(ctx context.Context) error {
+	var errs error
+	for i := range l.groupRequests {
+		if err := l.pollForLogs(ctx, &l.groupRequests[i]); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (l *logsReceiver) pollForLogs(ctx context.Context, gr *groupRequest) error {
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(gr.groupName),
+		NextToken:    gr.nextToken,
+	}
+	if l.cfg.Logs.MaxEventsPerRequest > 0 {
+		input.Limit = aws.Int64(int64(l.cfg.Logs.MaxEventsPerRequest))
+	}
+	if len(gr.names) > 0 {
+		input.LogStreamNames = gr.names
+	}
+	if gr.prefix != nil {
+		input.LogStreamNamePrefix = gr.prefix
+	}
+	switch {
+	case l.cfg.Logs.Groups.OneShot:
+		input.StartTime = aws.Int64(l.startTimeMs)
+		input.EndTime = aws.Int64(l.endTimeMs)
+	case gr.nextToken == nil && gr.lastEventTimestamp > 0:
+		// Starting a fresh scan, either the first tick or the one after a prior
+		// scan exhausted its NextToken: bound it to the watermark so a restart
+		// doesn't open a gap. StartTime is inclusive, so events already consumed
+		// at exactly this timestamp are dropped by dedupeAndAdvance below rather
+		// than excluded here.
+		input.StartTime = aws.Int64(gr.lastEventTimestamp)
+	}
+
+	for {
+		select {
+		case <-l.doneCh:
+			return nil
+		default:
+		}
+
+		out, err := l.client.FilterLogEventsWithContext(ctx, input)
+		if err != nil {
+			return fmt.Errorf("unable to filter log events for group %q: %w", gr.groupName, err)
+		}
+
+		events := gr.dedupeAndAdvance(out.Events)
+		if len(events) > 0 {
+			if err := l.consumer.ConsumeLogs(ctx, l.processEvents(gr.groupName, events)); err != nil {
+				return fmt.Errorf("unable to consume logs for group %q: %w", gr.groupName, err)
+			}
+		}
+
+		gr.nextToken = out.NextToken
+		if out.NextToken == nil {
+			return nil
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+func (l *logsReceiver) processEvents(groupName string, events []*cloudwatchlogs.FilteredLogEvent) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	resourceAttrs := rl.Resource().Attributes()
+	resourceAttrs.PutStr("cloud.provider", "aws")
+	resourceAttrs.PutStr("cloud.region", l.region)
+	resourceAttrs.PutStr("aws.log.group.name", groupName)
+	for k, v := range wellKnownGroupAttributes(groupName) {
+		resourceAttrs.PutStr(k, v)
+	}
+
+	l.applyResourceAttributeMapping(resourceAttrs, groupName)
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	for _, event := range events {
+		lr := sl.LogRecords().AppendEmpty()
+		lr.Body().SetStr(aws.StringValue(event.Message))
+		lr.SetTimestamp(pcommon.Timestamp(aws.Int64Value(event.Timestamp) * int64(time.Millisecond)))
+
+		attrs := lr.Attributes()
+		attrs.PutStr("aws.log.stream.name", aws.StringValue(event.LogStreamName))
+		attrs.PutStr("aws.log.event.id", aws.StringValue(event.EventId))
+		attrs.PutInt("aws.log.ingestion.time", aws.Int64Value(event.IngestionTime))
+
+		l.applyRecordAttributeMapping(attrs, groupName, eventTemplateData{
+			LogStreamName: aws.StringValue(event.LogStreamName),
+			EventID:       aws.StringValue(event.EventId),
+			IngestionTime: aws.Int64Value(event.IngestionTime),
+			Timestamp:     aws.Int64Value(event.Timestamp),
+		})
+	}
+
+	return logs
+}
+
+// applyResourceAttributeMapping executes the configured AttributeMapping's
+// "resource.*" templates once per log group and writes the results into
+// resourceAttrs. Unlike the per-record templates, these don't vary per event, so
+// callers run this once per group rather than once per event.
+func (l *logsReceiver) applyResourceAttributeMapping(resourceAttrs pcommon.Map, groupName string) {
+	if l.attributeMapping == nil {
+		return
+	}
+
+	data := eventTemplateData{
+		LogGroupName: groupName,
+		Tags:         l.groupTags[groupName],
+	}
+	for name, tmpl := range l.attributeMapping.resource {
+		v, err := execTemplate(tmpl, data)
+		if err != nil {
+			l.logger.Warn("attribute_mapping template failed", zap.String("attribute", "resource."+name), zap.Error(err))
+			continue
+		}
+		resourceAttrs.PutStr(name, v)
+	}
+}
+
+// applyRecordAttributeMapping executes the configured AttributeMapping's
+// "attributes.*" templates for a single event, writing the results into the log
+// record's own attrs. data carries the event-specific fields; LogGroupName and Tags
+// are filled in here so callers don't have to repeat them.
+func (l *logsReceiver) applyRecordAttributeMapping(attrs pcommon.Map, groupName string, data eventTemplateData) {
+	if l.attributeMapping == nil {
+		return
+	}
+
+	data.LogGroupName = groupName
+	data.Tags = l.groupTags[groupName]
+
+	for name, tmpl := range l.attributeMapping.record {
+		v, err := execTemplate(tmpl, data)
+		if err != nil {
+			l.logger.Warn("attribute_mapping template failed", zap.String("attribute", "attributes."+name), zap.Error(err))
+			continue
+		}
+		attrs.PutStr(name, v)
+	}
+}
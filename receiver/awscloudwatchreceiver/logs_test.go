@@ -16,9 +16,11 @@ package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,8 +29,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 
@@ -158,6 +162,7 @@ func TestShutdownWhileCollecting(t *testing.T) {
 	alertRcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
 	doneChan := make(chan time.Time, 1)
 	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
 	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
 		Events:    []*cloudwatchlogs.FilteredLogEvent{},
 		NextToken: aws.String("next"),
@@ -176,8 +181,699 @@ func TestShutdownWhileCollecting(t *testing.T) {
 	require.NoError(t, alertRcvr.Shutdown(context.Background()))
 }
 
+// Test that AttributeMapping templates and the well-known log group extractors
+// both land on the emitted logs, and that template values can reference a log
+// group's tags.
+func TestAttributeMapping(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.AttributeMapping = map[string]string{
+		"resource.service.name":    `{{ .LogGroupName | trimPrefix "/aws/lambda/" }}`,
+		"attributes.aws.log.owner": `{{ index .Tags "owner" }}`,
+	}
+	lambdaGroupName := "/aws/lambda/my-function"
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			lambdaGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{
+		Tags: map[string]*string{"owner": aws.String("platform-team")},
+	}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       &testEventID,
+				IngestionTime: aws.Int64(testIngestionTime),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+		NextToken: nil,
+	}, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	logs := sink.AllLogs()[0]
+	rl := logs.ResourceLogs().At(0)
+
+	faasName, ok := rl.Resource().Attributes().Get("faas.name")
+	require.True(t, ok)
+	require.Equal(t, "my-function", faasName.Str())
+
+	serviceName, ok := rl.Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	require.Equal(t, "my-function", serviceName.Str())
+
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	owner, ok := lr.Attributes().Get("aws.log.owner")
+	require.True(t, ok)
+	require.Equal(t, "platform-team", owner.Str())
+}
+
+// mapStorageClient is an in-memory storage.Client, standing in for the real
+// in-memory storage extension so the checkpointing tests don't need the whole
+// extension/host plumbing to exercise Get/Set/Close.
+type mapStorageClient struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMapStorageClient() *mapStorageClient {
+	return &mapStorageClient{data: map[string][]byte{}}
+}
+
+func (m *mapStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[key], nil
+}
+
+func (m *mapStorageClient) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *mapStorageClient) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *mapStorageClient) Batch(ctx context.Context, ops ...*storage.Operation) error {
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			v, err := m.Get(ctx, op.Key)
+			if err != nil {
+				return err
+			}
+			op.Value = v
+		case storage.Set:
+			if err := m.Set(ctx, op.Key, op.Value); err != nil {
+				return err
+			}
+		case storage.Delete:
+			if err := m.Delete(ctx, op.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mapStorageClient) Close(context.Context) error {
+	return nil
+}
+
+// Test that the EventId consumed before a restart is never consumed again after
+// one, since the checkpoint stored at Shutdown bounds the post-restart query.
+func TestNoDuplicateEventsAcrossRestart(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sharedStorage := newMapStorageClient()
+	sink := &consumertest.LogsSink{}
+
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.storageClient = sharedStorage
+	rcvr.client = defaultMockClient()
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	// Restart: a fresh receiver sharing the same storage client should pick up
+	// the persisted watermark and must not re-emit testEventID.
+	cfg.Logs.PollInterval = 50 * time.Millisecond
+	restarted := newLogsReceiver(cfg, zap.NewNop(), sink)
+	restarted.storageClient = sharedStorage
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.FilterLogEventsInput) bool {
+		return in.StartTime != nil && aws.Int64Value(in.StartTime) == testTimeStamp
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events:    []*cloudwatchlogs.FilteredLogEvent{},
+		NextToken: nil,
+	}, nil)
+	restarted.client = mc
+
+	require.NoError(t, restarted.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return len(mc.Calls) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, restarted.Shutdown(context.Background()))
+
+	seen := map[string]bool{}
+	for _, logs := range sink.AllLogs() {
+		rls := logs.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			sls := rls.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				lrs := sls.At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					id, ok := lrs.At(k).Attributes().Get("aws.log.event.id")
+					require.True(t, ok)
+					require.False(t, seen[id.Str()], "event %q consumed more than once across restart", id.Str())
+					seen[id.Str()] = true
+				}
+			}
+		}
+	}
+}
+
+// Test that a restart resumes on the previous run's max event Timestamp, not its
+// max IngestionTime, so an event ingested after the restart but timestamped before
+// that IngestionTime isn't silently dropped. testIngestionTime is well after
+// testTimeStamp, so an IngestionTime-based StartTime floor would exclude this event
+// entirely, opening a gap; a Timestamp-based floor must still return it.
+func TestNoGapForLateArrivingEventAcrossRestart(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = 1 * time.Second
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sharedStorage := newMapStorageClient()
+	sink := &consumertest.LogsSink{}
+
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.storageClient = sharedStorage
+	rcvr.client = defaultMockClient()
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	lateEventID := "late-arriving-event-id"
+	lateTimestamp := testTimeStamp + 1
+	require.Less(t, lateTimestamp, testIngestionTime)
+
+	cfg.Logs.PollInterval = 50 * time.Millisecond
+	restarted := newLogsReceiver(cfg, zap.NewNop(), sink)
+	restarted.storageClient = sharedStorage
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.FilterLogEventsInput) bool {
+		return in.StartTime != nil && aws.Int64Value(in.StartTime) == testTimeStamp
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       &lateEventID,
+				IngestionTime: aws.Int64(testIngestionTime + 1000),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(lateTimestamp),
+			},
+		},
+		NextToken: nil,
+	}, nil)
+	restarted.client = mc
+
+	require.NoError(t, restarted.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, restarted.Shutdown(context.Background()))
+
+	var found bool
+	for _, logs := range sink.AllLogs() {
+		rls := logs.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			sls := rls.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				lrs := sls.At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					id, ok := lrs.At(k).Attributes().Get("aws.log.event.id")
+					require.True(t, ok)
+					if id.Str() == lateEventID {
+						found = true
+					}
+				}
+			}
+		}
+	}
+	require.True(t, found, "event timestamped before the old IngestionTime watermark must still be consumed after a restart")
+}
+
+// Test that a one_shot receiver paginates across NextToken and then exits on its
+// own, without waiting for another PollInterval tick.
+func TestOneShotExitsAfterCompletion(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = time.Hour
+	cfg.Logs.Groups = GroupConfig{
+		StartTime: "-24h",
+		OneShot:   true,
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.FilterLogEventsInput) bool {
+		return in.NextToken == nil
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       aws.String("page-1-event"),
+				IngestionTime: aws.Int64(testIngestionTime),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+		NextToken: aws.String("page-2"),
+	}, nil)
+	mc.On("FilterLogEventsWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.FilterLogEventsInput) bool {
+		return in.NextToken != nil && *in.NextToken == "page-2"
+	}), mock.Anything).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []*cloudwatchlogs.FilteredLogEvent{
+			{
+				EventId:       aws.String("page-2-event"),
+				IngestionTime: aws.Int64(testIngestionTime),
+				LogStreamName: aws.String(testLogStreamName),
+				Message:       aws.String(testLogStreamMessage),
+				Timestamp:     aws.Int64(testTimeStamp),
+			},
+		},
+		NextToken: nil,
+	}, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The one-shot goroutine should have already returned on its own; Shutdown
+	// should not have to wait for a PollInterval tick that will never come.
+	done := make(chan struct{})
+	go func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return promptly after one-shot completion")
+	}
+}
+
+// Test that a completed one_shot backfill reports completion to the host, since
+// that's the only way the collector observes that this receiver has nothing left
+// to do.
+func TestOneShotReportsCompletionToHost(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.PollInterval = time.Hour
+	cfg.Logs.Groups = GroupConfig{
+		StartTime: "-24h",
+		OneShot:   true,
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.client = defaultMockClient()
+
+	host := newFakeHost()
+	require.NoError(t, rcvr.Start(context.Background(), host))
+
+	select {
+	case <-host.reported:
+	case <-time.After(2 * time.Second):
+		t.Fatal("one-shot backfill did not report completion to the host")
+	}
+
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+}
+
+// fakeHost wraps the standard nop test host, overriding ReportFatalError so tests
+// can observe when a receiver reports a terminal state.
+type fakeHost struct {
+	component.Host
+	reported chan struct{}
+}
+
+func newFakeHost() *fakeHost {
+	return &fakeHost{
+		Host:     componenttest.NewNopHost(),
+		reported: make(chan struct{}, 1),
+	}
+}
+
+func (f *fakeHost) ReportFatalError(error) {
+	select {
+	case f.reported <- struct{}{}:
+	default:
+	}
+}
+
+// Test to ensure that Shutdown returns promptly even while a live_tail session is
+// blocked waiting on the next event from its stream.
+func TestShutdownWhileLiveTailing(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Mode = LogsModeLiveTail
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			testLogGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	stream := newFakeLiveTailStream()
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
+	mc.On("StartLiveTailWithContext", mock.Anything, mock.Anything, mock.Anything).Return(stream, nil)
+	rcvr.client = mc
+
+	err := rcvr.Start(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	require.Never(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 1*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+}
+
+// Test that live_tail enriches events the same way the poll path does:
+// wellKnownGroupAttributes and AttributeMapping both apply.
+func TestLiveTailAppliesAttributeMapping(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Mode = LogsModeLiveTail
+	cfg.Logs.AttributeMapping = map[string]string{
+		"attributes.aws.log.owner": `{{ index .Tags "owner" }}`,
+	}
+	lambdaGroupName := "/aws/lambda/my-function"
+	cfg.Logs.Groups = GroupConfig{
+		NamedConfigs: map[string]StreamConfig{
+			lambdaGroupName: {
+				Names: []*string{&testLogStreamName},
+			},
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	stream := newFakeLiveTailStream()
+	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{
+		Tags: map[string]*string{"owner": aws.String("platform-team")},
+	}, nil)
+	mc.On("StartLiveTailWithContext", mock.Anything, mock.Anything, mock.Anything).Return(stream, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+
+	stream.events <- &cloudwatchlogs.LiveTailSessionUpdate{
+		SessionResults: []*cloudwatchlogs.LiveTailSessionLogResult{
+			{
+				EventId:            &testEventID,
+				IngestionTime:      aws.Int64(testIngestionTime),
+				LogGroupIdentifier: aws.String(lambdaGroupName),
+				LogStreamName:      aws.String(testLogStreamName),
+				Message:            aws.String(testLogStreamMessage),
+				Timestamp:          aws.Int64(testTimeStamp),
+			},
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+
+	faasName, ok := rl.Resource().Attributes().Get("faas.name")
+	require.True(t, ok)
+	require.Equal(t, "my-function", faasName.Str())
+
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	owner, ok := lr.Attributes().Get("aws.log.owner")
+	require.True(t, ok)
+	require.Equal(t, "platform-team", owner.Str())
+}
+
+// fakeRegionProvider is a regionProvider test double standing in for a real
+// EC2 IMDS or ECS task metadata endpoint.
+type fakeRegionProvider struct {
+	src      string
+	resolved string
+	err      error
+}
+
+func (f *fakeRegionProvider) source() string { return f.src }
+
+func (f *fakeRegionProvider) region(context.Context) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.resolved, nil
+}
+
+// Test that an explicitly configured Region is used as-is and discovery is never
+// attempted, even when a regionProvider is set.
+func TestRegionDiscoveryExplicitRegionWins(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups.AutodiscoverConfig = nil
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.client = defaultMockClient()
+	rcvr.regionProvider = &fakeRegionProvider{err: errors.New("should not be called")}
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Equal(t, "us-west-1", rcvr.region)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+}
+
+// Test that an empty Region falls back to whatever the regionProvider resolves.
+func TestRegionDiscoveryFallsBackToProvider(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Groups.AutodiscoverConfig = nil
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.client = defaultMockClient()
+	rcvr.regionProvider = &fakeRegionProvider{src: "ec2_imds", resolved: "eu-central-1"}
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Equal(t, "eu-central-1", rcvr.region)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+}
+
+// Test that a regionProvider failure surfaces a clear error from Start instead of
+// continuing with an empty region.
+func TestRegionDiscoveryFailureSurfacesFromStart(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Groups.AutodiscoverConfig = nil
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+	rcvr.client = defaultMockClient()
+	rcvr.regionProvider = &fakeRegionProvider{src: "ec2_imds", err: errors.New("metadata endpoint unreachable")}
+
+	err := rcvr.Start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "region was not configured and could not be discovered")
+}
+
+// Test that successive Logs Insights queries slide their [StartTime, EndTime]
+// window forward, each one TimeWindow wide.
+func TestInsightsWindowSlides(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		InsightsConfig: &InsightsConfig{
+			QueryString:   "fields @timestamp, @message",
+			LogGroupNames: []string{testLogGroupName},
+			TimeWindow:    5 * time.Minute,
+			Schedule:      50 * time.Millisecond,
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	var starts []int64
+	var mu sync.Mutex
+	mc := &mockClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.StartQueryInput) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		window := aws.Int64Value(in.EndTime) - aws.Int64Value(in.StartTime)
+		if window != int64((5 * time.Minute).Seconds()) {
+			return false
+		}
+		starts = append(starts, aws.Int64Value(in.StartTime))
+		return true
+	}), mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-1")}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+		Status:  aws.String(cloudwatchlogs.QueryStatusComplete),
+		Results: [][]*cloudwatchlogs.ResultField{},
+	}, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(starts) >= 2
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Greater(t, starts[len(starts)-1], starts[0], "later queries should slide the window forward")
+}
+
+// Test that Shutdown cancels an in-flight Logs Insights query via StopQuery
+// instead of waiting for it to finish on its own.
+func TestInsightsQueryCancelledOnShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		InsightsConfig: &InsightsConfig{
+			QueryString:        "fields @timestamp, @message",
+			LogGroupNamePrefix: "/aws/",
+			TimeWindow:         5 * time.Minute,
+			Schedule:           time.Hour,
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	mc := &mockClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-1")}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+		Status: aws.String(cloudwatchlogs.QueryStatusRunning),
+	}, nil)
+	mc.On("StopQueryWithContext", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.StopQueryInput) bool {
+		return aws.StringValue(in.QueryId) == "query-1"
+	}), mock.Anything).Return(&cloudwatchlogs.StopQueryOutput{}, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+
+	done := make(chan struct{})
+	go func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Shutdown did not cancel the in-flight Insights query promptly")
+	}
+
+	mc.AssertCalled(t, "StopQueryWithContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test that a completed Logs Insights query's result field lists become log
+// record attributes, with the "@message" field doubling as the record body.
+func TestInsightsResultsMapToAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Region = "us-west-1"
+	cfg.Logs.Groups = GroupConfig{
+		InsightsConfig: &InsightsConfig{
+			QueryString:   "fields @timestamp, @message",
+			LogGroupNames: []string{testLogGroupName},
+			TimeWindow:    5 * time.Minute,
+			Schedule:      time.Hour,
+		},
+	}
+
+	sink := &consumertest.LogsSink{}
+	rcvr := newLogsReceiver(cfg, zap.NewNop(), sink)
+
+	mc := &mockClient{}
+	mc.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-1")}, nil)
+	mc.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+		Status: aws.String(cloudwatchlogs.QueryStatusComplete),
+		Results: [][]*cloudwatchlogs.ResultField{
+			{
+				{Field: aws.String("@timestamp"), Value: aws.String("2022-10-07 18:10:46.000")},
+				{Field: aws.String("@message"), Value: aws.String(testLogStreamMessage)},
+			},
+		},
+	}, nil)
+	rcvr.client = mc
+
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	logs := sink.AllLogs()[0]
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, testLogStreamMessage, lr.Body().Str())
+
+	ts, ok := lr.Attributes().Get("@timestamp")
+	require.True(t, ok)
+	require.Equal(t, "2022-10-07 18:10:46.000", ts.Str())
+}
+
 func defaultMockClient() client {
 	mc := &mockClient{}
+	mc.On("ListTagsLogGroupWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.ListTagsLogGroupOutput{}, nil)
 	mc.On("DescribeLogGroupsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(
 		&cloudwatchlogs.DescribeLogGroupsOutput{
 			LogGroups: []*cloudwatchlogs.LogGroup{
@@ -227,6 +923,66 @@ func (mc *mockClient) FilterLogEventsWithContext(ctx context.Context, input *clo
 	return args.Get(0).(*cloudwatchlogs.FilterLogEventsOutput), args.Error(1)
 }
 
+func (mc *mockClient) StartLiveTailWithContext(ctx context.Context, input *cloudwatchlogs.StartLiveTailInput, opts ...request.Option) (liveTailStream, error) {
+	args := mc.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(liveTailStream), args.Error(1)
+}
+
+func (mc *mockClient) ListTagsLogGroupWithContext(ctx context.Context, input *cloudwatchlogs.ListTagsLogGroupInput, opts ...request.Option) (*cloudwatchlogs.ListTagsLogGroupOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.ListTagsLogGroupOutput), args.Error(1)
+}
+
+func (mc *mockClient) StartQueryWithContext(ctx context.Context, input *cloudwatchlogs.StartQueryInput, opts ...request.Option) (*cloudwatchlogs.StartQueryOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.StartQueryOutput), args.Error(1)
+}
+
+func (mc *mockClient) GetQueryResultsWithContext(ctx context.Context, input *cloudwatchlogs.GetQueryResultsInput, opts ...request.Option) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.GetQueryResultsOutput), args.Error(1)
+}
+
+func (mc *mockClient) StopQueryWithContext(ctx context.Context, input *cloudwatchlogs.StopQueryInput, opts ...request.Option) (*cloudwatchlogs.StopQueryOutput, error) {
+	args := mc.Called(ctx, input, opts)
+	return args.Get(0).(*cloudwatchlogs.StopQueryOutput), args.Error(1)
+}
+
+// fakeLiveTailStream is a channel-backed liveTailStream used to drive the live tail
+// read loop from tests without depending on the concrete AWS event stream type.
+type fakeLiveTailStream struct {
+	events  chan cloudwatchlogs.StartLiveTailResponseStreamEvent
+	closeCh chan struct{}
+	err     error
+}
+
+func newFakeLiveTailStream() *fakeLiveTailStream {
+	return &fakeLiveTailStream{
+		events:  make(chan cloudwatchlogs.StartLiveTailResponseStreamEvent, 10),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (f *fakeLiveTailStream) Events() <-chan cloudwatchlogs.StartLiveTailResponseStreamEvent {
+	return f.events
+}
+
+func (f *fakeLiveTailStream) Close() error {
+	select {
+	case <-f.closeCh:
+	default:
+		close(f.closeCh)
+	}
+	return nil
+}
+
+func (f *fakeLiveTailStream) Err() error {
+	return f.err
+}
+
 func readLogs(path string) (plog.Logs, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awscloudwatchreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awscloudwatchreceiver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/zap"
+)
+
+// ecsTaskMetadataRegionEnvVar is set by the ECS agent on every task that opted
+// into the task metadata endpoint (metadata v4). Its presence is also how the
+// Docker awslogs driver decides whether it's running inside ECS.
+const ecsTaskMetadataRegionEnvVar = "ECS_CONTAINER_METADATA_URI_V4"
+
+var taskARNRegionPattern = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:ecs:([a-z0-9-]+):`)
+
+// regionProvider discovers the AWS region the receiver is running in when Region
+// isn't set explicitly. It's an interface purely so tests can substitute a fake
+// without standing up a real EC2/ECS metadata endpoint.
+type regionProvider interface {
+	// source names where the region came from, for the one-line log message
+	// emitted after a successful discovery.
+	source() string
+	region(ctx context.Context) (string, error)
+}
+
+type ec2IMDSRegionProvider struct {
+	metadata *ec2metadata.EC2Metadata
+}
+
+func newEC2IMDSRegionProvider(endpoint string) (*ec2IMDSRegionProvider, error) {
+	cfg := aws.NewConfig()
+	if endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create AWS session for IMDS: %w", err)
+	}
+	return &ec2IMDSRegionProvider{metadata: ec2metadata.New(sess)}, nil
+}
+
+func (p *ec2IMDSRegionProvider) source() string { return "ec2_imds" }
+
+func (p *ec2IMDSRegionProvider) region(_ context.Context) (string, error) {
+	return p.metadata.Region()
+}
+
+// ecsTaskMetadataRegionProvider derives the region from the task ARN reported by
+// the ECS task metadata v4 endpoint, whose address is given to every task by the
+// ECS agent in ECS_CONTAINER_METADATA_URI_V4.
+type ecsTaskMetadataRegionProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newECSTaskMetadataRegionProvider(endpoint string) *ecsTaskMetadataRegionProvider {
+	return &ecsTaskMetadataRegionProvider{endpoint: endpoint, client: http.DefaultClient}
+}
+
+func (p *ecsTaskMetadataRegionProvider) source() string { return "ecs_task_metadata" }
+
+func (p *ecsTaskMetadataRegionProvider) region(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/task", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to reach ECS task metadata endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var task struct {
+		TaskARN string `json:"TaskARN"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return "", fmt.Errorf("unable to decode ECS task metadata response: %w", err)
+	}
+
+	m := taskARNRegionPattern.FindStringSubmatch(task.TaskARN)
+	if m == nil {
+		return "", fmt.Errorf("could not parse region from task ARN %q", task.TaskARN)
+	}
+	return m[1], nil
+}
+
+// discoverRegion picks the metadata provider appropriate to the environment and
+// resolves the region through it, the same fallback order
+// newAWSLogsClient in the Docker awslogs driver uses: ECS task metadata if the
+// container is running under ECS, otherwise the EC2 instance metadata service.
+func (l *logsReceiver) discoverRegion(ctx context.Context) error {
+	if l.region != "" {
+		return nil
+	}
+
+	provider := l.regionProvider
+	if provider == nil {
+		if uri := os.Getenv(ecsTaskMetadataRegionEnvVar); uri != "" {
+			provider = newECSTaskMetadataRegionProvider(uri)
+		} else {
+			p, err := newEC2IMDSRegionProvider(l.cfg.IMDSEndpoint)
+			if err != nil {
+				return err
+			}
+			provider = p
+		}
+	}
+
+	region, err := provider.region(ctx)
+	if err != nil {
+		return fmt.Errorf("region was not configured and could not be discovered via %s: %w", provider.source(), err)
+	}
+
+	l.logger.Info("discovered AWS region", zap.String("region", region), zap.String("source", provider.source()))
+	l.region = region
+	return nil
+}